@@ -0,0 +1,175 @@
+// Command game-of-life-server is the authoritative board for multiplayer
+// games: it owns the field, runs the simulation and broadcasts diffs to
+// every connected game-of-life client.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	stdnet "net"
+	"os"
+	"sync"
+	"time"
+
+	gonet "github.com/alaruss/game_of_life/net"
+	"github.com/alaruss/game_of_life/sim"
+)
+
+type player struct {
+	id   int
+	name string
+	conn *gonet.Conn
+}
+
+type server struct {
+	mu        sync.Mutex
+	board     *sim.Board
+	evolution bool
+	delay     time.Duration
+	nextID    int
+	players   map[int]*player
+}
+
+func newServer(w, h int, rule sim.Rule) *server {
+	return &server{
+		board:   sim.NewBoard(w, h, rule),
+		delay:   500 * time.Millisecond,
+		players: make(map[int]*player),
+	}
+}
+
+// tick advances the board by one generation, sharing sim.Board with the
+// client so the two can't simulate different rules, and returns the
+// cells that changed in the wire format.
+func (s *server) tick() []gonet.CellDiff {
+	var diffs []gonet.CellDiff
+	for _, d := range s.board.Tick() {
+		diffs = append(diffs, gonet.CellDiff{X: d.X, Y: d.Y, State: d.State})
+	}
+	return diffs
+}
+
+func (s *server) broadcast(typ gonet.MessageType, payload interface{}) {
+	for _, p := range s.players {
+		if err := p.conn.Send(typ, payload); err != nil {
+			log.Printf("send to %s: %v", p.name, err)
+		}
+	}
+}
+
+func (s *server) runTicker() {
+	for {
+		s.mu.Lock()
+		delay := s.delay
+		evolving := s.evolution
+		s.mu.Unlock()
+		time.Sleep(delay)
+		s.mu.Lock()
+		if evolving {
+			diffs := s.tick()
+			if len(diffs) > 0 {
+				s.broadcast(gonet.TypeTick, gonet.Tick{Generation: s.board.Generation, Diffs: diffs})
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *server) handleConn(raw stdnet.Conn) {
+	defer raw.Close()
+	conn := gonet.NewConn(raw)
+
+	env, err := conn.Receive()
+	if err != nil {
+		log.Printf("handshake: %v", err)
+		return
+	}
+	var hello gonet.Hello
+	if err := env.Decode(&hello); err != nil {
+		log.Printf("handshake: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	p := &player{id: s.nextID, name: hello.Name, conn: conn}
+	s.players[p.id] = p
+	hello.PlayerID = p.id
+	conn.Send(gonet.TypeResize, gonet.Resize{Width: s.board.Width, Height: s.board.Height, Rule: s.board.Rule.String()})
+	conn.Send(gonet.TypeMode, gonet.Mode{Evolution: s.evolution, DelayMs: int(s.delay / time.Millisecond)})
+	s.broadcast(gonet.TypeHello, hello)
+	s.mu.Unlock()
+
+	log.Printf("%s joined as player %d", p.name, p.id)
+	defer func() {
+		s.mu.Lock()
+		delete(s.players, p.id)
+		s.mu.Unlock()
+		log.Printf("%s left", p.name)
+	}()
+
+	for {
+		env, err := conn.Receive()
+		if err != nil {
+			return
+		}
+		switch env.Type {
+		case gonet.TypeToggle:
+			var t gonet.Toggle
+			if err := env.Decode(&t); err != nil {
+				continue
+			}
+			t.PlayerID = p.id
+			s.mu.Lock()
+			if t.X >= 0 && t.X < s.board.Width && t.Y >= 0 && t.Y < s.board.Height {
+				s.board.Set(t.X, t.Y, s.board.Get(t.X, t.Y)^1)
+				s.broadcast(gonet.TypeToggle, t)
+			}
+			s.mu.Unlock()
+		case gonet.TypeMode:
+			var m gonet.Mode
+			if err := env.Decode(&m); err != nil {
+				continue
+			}
+			s.mu.Lock()
+			s.evolution = m.Evolution
+			if m.DelayMs > 0 {
+				s.delay = time.Duration(m.DelayMs) * time.Millisecond
+			}
+			s.broadcast(gonet.TypeMode, m)
+			s.mu.Unlock()
+		}
+	}
+}
+
+func main() {
+	addr := flag.String("addr", ":4567", "address to listen on")
+	width := flag.Int("width", 80, "board width")
+	height := flag.Int("height", 24, "board height")
+	ruleFlag := flag.String("rule", "B3/S23", "Life-like rule in B.../S... notation; announced to every client on join")
+	flag.Parse()
+
+	rule, err := sim.ParseRule(*ruleFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	s := newServer(*width, *height, rule)
+	go s.runTicker()
+
+	ln, err := stdnet.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("game-of-life-server listening on %s (%dx%d, %s)\n", *addr, *width, *height, rule.String())
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("accept: %v", err)
+			continue
+		}
+		go s.handleConn(conn)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/alaruss/game_of_life/sim"
+)
+
+// headlessOptions configures a -headless run.
+type headlessOptions struct {
+	width       int
+	height      int
+	rule        sim.Rule
+	generations int
+	seed        int64
+	density     float64
+	pattern     string
+	hash        bool
+}
+
+// headlessResult is emitted as JSON on stdout by a -headless run.
+type headlessResult struct {
+	Generations int     `json:"generations"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	Rule        string  `json:"rule"`
+	WallTimeMs  float64 `json:"wall_time_ms"`
+	AvgTickUs   float64 `json:"avg_tick_us"`
+	LiveCells   int     `json:"live_cells"`
+	Hash        string  `json:"hash,omitempty"`
+}
+
+// runHeadless seeds a board either from a pattern file or a seeded PRNG
+// and advances it opts.generations times with no tcell screen involved,
+// sharing sim.Board with the interactive UI so the two can't diverge.
+func runHeadless(opts headlessOptions) (headlessResult, error) {
+	if opts.width <= 0 || opts.height <= 0 {
+		return headlessResult{}, fmt.Errorf("headless: width and height must be positive, got %dx%d", opts.width, opts.height)
+	}
+	board := sim.NewBoard(opts.width, opts.height, opts.rule)
+
+	if opts.pattern != "" {
+		f, err := os.Open(opts.pattern)
+		if err != nil {
+			return headlessResult{}, err
+		}
+		defer f.Close()
+		if err := board.LoadRLE(f, 0, 0); err != nil {
+			return headlessResult{}, err
+		}
+	} else {
+		rnd := rand.New(rand.NewSource(opts.seed))
+		board.SeedRandom(rnd, opts.density)
+	}
+
+	start := time.Now()
+	for i := 0; i < opts.generations; i++ {
+		board.Tick()
+	}
+	elapsed := time.Since(start)
+
+	result := headlessResult{
+		Generations: board.Generation,
+		Width:       board.Width,
+		Height:      board.Height,
+		Rule:        board.Rule.String(),
+		WallTimeMs:  float64(elapsed) / float64(time.Millisecond),
+		LiveCells:   board.LiveCount(),
+	}
+	if opts.generations > 0 {
+		result.AvgTickUs = float64(elapsed) / float64(opts.generations) / float64(time.Microsecond)
+	}
+	if opts.hash {
+		h := board.Hash()
+		result.Hash = hex.EncodeToString(h[:])
+	}
+	return result, nil
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/gdamore/tcell"
 	"github.com/mattn/go-runewidth"
+
+	gonet "github.com/alaruss/game_of_life/net"
+	"github.com/alaruss/game_of_life/sim"
 )
 
+// defaultDelay is the tick delay (in milliseconds) a hard reset restores.
+const defaultDelay = time.Duration(500)
+
 func createScreen() (tcell.Screen, error) {
 	tcell.SetEncodingFallback(tcell.EncodingFallbackASCII)
 	s, e := tcell.NewScreen()
@@ -29,23 +37,25 @@ func createScreen() (tcell.Screen, error) {
 
 // GameOfLife - a struct for game of life
 type GameOfLife struct {
-	width      int
-	height     int
-	evolution  bool
-	delay      time.Duration
-	field      map[int]map[int]byte
-	screen     tcell.Screen
-	stop       chan struct{}
-	generation int
-	stateWidth int
-}
-
-func makeNewMap(w int, h int) map[int]map[int]byte {
-	m := make(map[int]map[int]byte, h)
-	for i := 0; i < h; i++ {
-		m[i] = make(map[int]byte, w)
-	}
-	return m
+	board        *sim.Board
+	evolution    bool
+	delay        time.Duration
+	screen       tcell.Screen
+	stop         chan struct{}
+	stateWidth   int
+	patternsDir  string
+	picker       *patternPicker
+	netConn      *gonet.Conn
+	playerID     int
+	playerName   string
+	players      map[int]string
+	prompt       *textPrompt
+	buffer       []tile
+	prevBuffer   []tile
+	showStats    bool
+	stats        liveStats
+	lastTickAt   time.Time
+	lastRenderAt time.Time
 }
 
 // NewGame - create a new game object
@@ -53,60 +63,34 @@ func NewGame(screen tcell.Screen) *GameOfLife {
 	w, h := screen.Size()
 	w = w - 10
 	game := GameOfLife{
-		width:      w,
-		height:     h,
-		delay:      500,
+		board:      sim.NewBoard(w, h, sim.ConwayRule),
+		delay:      defaultDelay,
 		screen:     screen,
-		field:      makeNewMap(w, h),
 		stateWidth: 10,
 	}
+	game.allocBuffers()
 
 	return &game
 }
 
-func (game *GameOfLife) nextCellState(x int, y int) byte {
-	neigbors := 0
-	for i := -1; i <= 1; i++ {
-		for j := -1; j <= 1; j++ {
-			nx := x + i
-			ny := y + j
-			if nx < 0 {
-				nx = game.width - 1
-			} else if nx == game.width {
-				nx = 0
-			}
-			if ny < 0 {
-				ny = game.height - 1
-			} else if ny == game.height {
-				ny = 0
-			}
-			neigbors += int(game.field[ny][nx])
-		}
-	}
-	if neigbors == 3 {
-		return 1
-	} else if neigbors == 4 {
-		return game.field[y][x]
-	}
-	return 0
-}
 func (game *GameOfLife) tick() {
-	nextMap := makeNewMap(game.width, game.height)
-	for y := 0; y < game.height; y++ {
-		for x := 0; x <= game.width; x++ {
-			nextMap[y][x] = game.nextCellState(x, y)
-			if nextMap[y][x] != game.field[y][x] {
-				game.drawCell(x, y, nextMap[y][x])
-			}
+	now := time.Now()
+	if !game.lastTickAt.IsZero() {
+		if d := now.Sub(game.lastTickAt); d > 0 {
+			game.stats.tps = float64(time.Second) / float64(d)
 		}
 	}
-	game.field = nextMap
-	game.generation++
+	game.lastTickAt = now
+
+	for _, d := range game.board.Tick() {
+		game.drawCell(d.X, d.Y, d.State)
+	}
 }
 
 func (game *GameOfLife) reverseCell(x int, y int) {
-	game.field[y][x] ^= 1
-	game.drawCell(x, y, game.field[y][x])
+	state := game.board.Get(x, y) ^ 1
+	game.board.Set(x, y, state)
+	game.drawCell(x, y, state)
 }
 
 func (game *GameOfLife) switchMode() {
@@ -117,27 +101,30 @@ func (game *GameOfLife) switchMode() {
 	}
 }
 
-func (game *GameOfLife) drawCell(x int, y int, state byte) {
-	if state == 1 {
-		game.screen.SetContent(x+game.stateWidth, y, 'X', nil, tcell.StyleDefault)
-	} else {
-		game.screen.SetContent(x+game.stateWidth, y, ' ', nil, tcell.StyleDefault)
-	}
-}
-
 func (game *GameOfLife) drawState() {
+	lastRow := game.promptRow()
 	for i := 0; i <= game.stateWidth; i++ {
-		for j := 0; j <= 2; j++ {
-			game.screen.SetContent(i, 0, ' ', nil, tcell.StyleDefault)
+		for j := 0; j <= lastRow; j++ {
+			game.screen.SetContent(i, j, ' ', nil, tcell.StyleDefault)
 		}
 	}
-	emitStr(game.screen, 0, 0, tcell.StyleDefault, fmt.Sprintf("%dx%d", game.width, game.height))
+	emitStr(game.screen, 0, 0, tcell.StyleDefault, fmt.Sprintf("%dx%d", game.board.Width, game.board.Height))
 	state := "Pause"
 	if game.evolution {
 		state = "Play"
 	}
 	emitStr(game.screen, 0, 1, tcell.StyleDefault, state)
-	emitStr(game.screen, 0, 2, tcell.StyleDefault, fmt.Sprintf("Gen: %d", game.generation))
+	emitStr(game.screen, 0, 2, tcell.StyleDefault, fmt.Sprintf("Gen: %d", game.board.Generation))
+	emitStr(game.screen, 0, 3, tcell.StyleDefault, game.board.Rule.String())
+	row := 4
+	if game.showStats {
+		emitStr(game.screen, 0, row, tcell.StyleDefault, game.stats.String())
+		row++
+	}
+	for id, name := range game.players {
+		emitStr(game.screen, 0, row, playerStyle(id), name)
+		row++
+	}
 }
 
 func (game *GameOfLife) increaseDelay() {
@@ -155,35 +142,46 @@ func (game *GameOfLife) decreaseDelay() {
 func (game *GameOfLife) resize(w int, h int) {
 	game.evolution = false
 	w = w - game.stateWidth
-	game.screen.Fill(' ', tcell.StyleDefault)
-	newField := makeNewMap(w, h)
-	for y := 0; y < h; y++ {
-		if y >= game.height {
-			break
-		}
-		for x := 0; x <= w; x++ {
-			newField[y][x] = game.field[y][x]
-			if newField[y][x] == 1 {
-				game.drawCell(x, y, newField[y][x])
-			}
-			if x >= game.width {
-				break
-			}
-		}
-	}
-	game.width = w
-	game.height = h
-	game.field = newField
+	game.replaceBoard(game.board.Resize(w, h))
 }
 
 // Start - start game's loop
 func (game *GameOfLife) Start() {
 	game.stop = make(chan struct{})
+	if game.netConn != nil {
+		go game.netLoop()
+	}
 	go func() {
 		for {
 			ev := game.screen.PollEvent()
 			switch ev := ev.(type) {
 			case *tcell.EventKey:
+				if game.prompt != nil {
+					switch ev.Key() {
+					case tcell.KeyEscape:
+						game.closePrompt()
+					case tcell.KeyEnter:
+						game.submitPrompt()
+					case tcell.KeyBackspace, tcell.KeyBackspace2:
+						game.promptBackspace()
+					case tcell.KeyRune:
+						game.promptAppend(ev.Rune())
+					}
+					continue
+				}
+				if game.picker != nil {
+					switch ev.Key() {
+					case tcell.KeyEscape:
+						game.closePicker()
+					case tcell.KeyEnter:
+						game.selectPicker()
+					case tcell.KeyUp:
+						game.movePicker(-1)
+					case tcell.KeyDown:
+						game.movePicker(1)
+					}
+					continue
+				}
 				switch ev.Key() {
 				case tcell.KeyEscape, tcell.KeyEnter:
 					close(game.stop)
@@ -195,9 +193,47 @@ func (game *GameOfLife) Start() {
 				case tcell.KeyRune:
 					switch ev.Rune() {
 					case ' ':
+						if game.netConn != nil {
+							game.netConn.Send(gonet.TypeMode, gonet.Mode{Evolution: !game.evolution})
+							continue
+						}
 						game.switchMode()
 						game.drawState()
 						game.screen.Show()
+					case 'l', 'L':
+						if game.netConn != nil {
+							continue
+						}
+						game.openPicker()
+					case 'c', 'C':
+						if game.netConn != nil {
+							continue
+						}
+						game.board.Rule = sim.NextRule(game.board.Rule)
+						game.drawState()
+						game.screen.Show()
+					case 's':
+						if game.netConn != nil {
+							continue
+						}
+						game.openSavePrompt()
+					case 'o':
+						if game.netConn != nil {
+							continue
+						}
+						game.openLoadPrompt()
+					case 'r':
+						if game.netConn != nil {
+							continue
+						}
+						game.softReset()
+					case 'R':
+						if game.netConn != nil {
+							continue
+						}
+						game.hardReset()
+					case 'f', 'F':
+						game.toggleStats()
 					case 'q', 'Q':
 						close(game.stop)
 						return
@@ -207,8 +243,12 @@ func (game *GameOfLife) Start() {
 				if ev.Buttons()&tcell.Button1 != 0 {
 					x, y := ev.Position()
 					if x >= game.stateWidth {
-						game.reverseCell(x-game.stateWidth, y)
-						game.screen.Show()
+						if game.netConn != nil {
+							game.sendToggle(x-game.stateWidth, y)
+						} else {
+							game.reverseCell(x-game.stateWidth, y)
+							game.render()
+						}
 					}
 				}
 			case *tcell.EventResize:
@@ -226,10 +266,10 @@ func (game *GameOfLife) Start() {
 			return
 		case <-time.After(time.Millisecond * game.delay):
 		}
-		if game.evolution {
+		if game.evolution && game.netConn == nil {
 			game.tick()
 			game.drawState()
-			game.screen.Show()
+			game.render()
 		}
 	}
 }
@@ -249,14 +289,88 @@ func emitStr(s tcell.Screen, x, y int, style tcell.Style, str string) {
 }
 
 func main() {
+	patternsDir := flag.String("patterns", "", "directory of .rle pattern files browsable with the 'l' key")
+	ruleFlag := flag.String("rule", "B3/S23", "Life-like rule in B.../S... notation")
+	connect := flag.String("connect", "", "host:port of a game-of-life-server to join instead of simulating locally")
+	name := flag.String("name", "player", "player name announced to the server when using -connect")
+	cpuProfile := flag.String("cpuprofile", "", "write a pprof CPU profile to this file")
+	memProfile := flag.String("memprofile", "", "write a pprof heap profile to this file on exit")
+	headless := flag.Bool("headless", false, "run a non-interactive simulation and print stats as JSON instead of opening the UI")
+	generations := flag.Int("generations", 100, "generations to run in -headless mode")
+	seed := flag.Int64("seed", 1, "PRNG seed used to randomly fill the board in -headless mode")
+	density := flag.Float64("density", 0.2, "live-cell density in [0,1] used to randomly fill the board in -headless mode")
+	width := flag.Int("width", 80, "board width in -headless mode")
+	height := flag.Int("height", 24, "board height in -headless mode")
+	pattern := flag.String("pattern", "", "load this RLE file instead of random seeding in -headless mode")
+	withHash := flag.Bool("hash", false, "include a SHA-1 hash of the final field in -headless output")
+	flag.Parse()
+
+	rule, err := sim.ParseRule(*ruleFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if *cpuProfile != "" {
+		stop, err := startCPUProfile(*cpuProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer stop()
+	}
+
+	if *headless {
+		result, err := runHeadless(headlessOptions{
+			width:       *width,
+			height:      *height,
+			rule:        rule,
+			generations: *generations,
+			seed:        *seed,
+			density:     *density,
+			pattern:     *pattern,
+			hash:        *withHash,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if *memProfile != "" {
+			if err := writeHeapProfile(*memProfile); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+		}
+		return
+	}
+
 	screen, err := createScreen()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 	game := NewGame(screen)
+	game.patternsDir = *patternsDir
+	game.board.Rule = rule
+
+	if *connect != "" {
+		game.playerName = *name
+		if err := game.Connect(*connect, *name); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	game.Start()
 
 	screen.Fini()
+
+	if *memProfile != "" {
+		if err := writeHeapProfile(*memProfile); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}
 }
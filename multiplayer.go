@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gdamore/tcell"
+
+	gonet "github.com/alaruss/game_of_life/net"
+	"github.com/alaruss/game_of_life/sim"
+)
+
+// playerPalette gives each connected player a foreground color for the
+// cells they toggle and their name in the player list; ids past the end
+// of the palette wrap around to its start. Remote cursors are not drawn.
+var playerPalette = []tcell.Color{
+	tcell.ColorGreen,
+	tcell.ColorYellow,
+	tcell.ColorBlue,
+	tcell.ColorFuchsia,
+	tcell.ColorAqua,
+	tcell.ColorOrange,
+}
+
+func playerStyle(playerID int) tcell.Style {
+	color := playerPalette[playerID%len(playerPalette)]
+	return tcell.StyleDefault.Foreground(color)
+}
+
+// Connect dials a game-of-life-server at addr, sends the Hello frame
+// and puts game into networked mode: local simulation is suppressed in
+// favor of diffs received from the server. The server's echo of the
+// Hello, carrying this player's id, is handled later by netLoop.
+func (game *GameOfLife) Connect(addr string, name string) error {
+	raw, err := net.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	conn := gonet.NewConn(raw)
+	if err := conn.Send(gonet.TypeHello, gonet.Hello{Name: name}); err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	game.netConn = conn
+	game.players = make(map[int]string)
+	return nil
+}
+
+// sendToggle forwards a cell toggle to the server instead of flipping it
+// locally; the flip is applied once the server echoes it back.
+func (game *GameOfLife) sendToggle(x int, y int) {
+	if game.netConn == nil {
+		return
+	}
+	game.netConn.Send(gonet.TypeToggle, gonet.Toggle{X: x, Y: y, PlayerID: game.playerID})
+}
+
+// netLoop receives frames from the server until the connection closes
+// and applies them to the local view.
+func (game *GameOfLife) netLoop() {
+	for {
+		env, err := game.netConn.Receive()
+		if err != nil {
+			close(game.stop)
+			return
+		}
+		switch env.Type {
+		case gonet.TypeHello:
+			var h gonet.Hello
+			if env.Decode(&h) != nil {
+				continue
+			}
+			if h.Name == game.playerName {
+				game.playerID = h.PlayerID
+			}
+			game.players[h.PlayerID] = h.Name
+			game.drawState()
+			game.screen.Show()
+		case gonet.TypeResize:
+			var r gonet.Resize
+			if env.Decode(&r) != nil {
+				continue
+			}
+			rule := game.board.Rule
+			if parsed, err := sim.ParseRule(r.Rule); err == nil {
+				rule = parsed
+			}
+			game.replaceBoard(sim.NewBoard(r.Width, r.Height, rule))
+			game.screen.Sync()
+		case gonet.TypeMode:
+			var m gonet.Mode
+			if env.Decode(&m) != nil {
+				continue
+			}
+			game.evolution = m.Evolution
+			if m.DelayMs > 0 {
+				game.delay = time.Duration(m.DelayMs)
+			}
+			game.drawState()
+			game.screen.Show()
+		case gonet.TypeToggle:
+			var t gonet.Toggle
+			if env.Decode(&t) != nil {
+				continue
+			}
+			if t.X < 0 || t.Y < 0 || t.X >= game.board.Width || t.Y >= game.board.Height {
+				continue
+			}
+			state := game.board.Get(t.X, t.Y) ^ 1
+			game.board.Set(t.X, t.Y, state)
+			game.drawCellStyled(t.X, t.Y, state, playerStyle(t.PlayerID))
+			game.render()
+		case gonet.TypeTick:
+			var tk gonet.Tick
+			if env.Decode(&tk) != nil {
+				continue
+			}
+			for _, d := range tk.Diffs {
+				if d.X < 0 || d.Y < 0 || d.X >= game.board.Width || d.Y >= game.board.Height {
+					continue
+				}
+				game.board.Set(d.X, d.Y, d.State)
+				game.drawCell(d.X, d.Y, d.State)
+			}
+			game.board.Generation = tk.Generation
+			game.drawState()
+			game.render()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package net implements the length-prefixed JSON wire protocol used to
+// synchronize a board between the game-of-life-server and its clients.
+package net
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MessageType identifies the payload carried by an Envelope.
+type MessageType string
+
+// Frame types exchanged between client and server.
+const (
+	TypeHello  MessageType = "hello"
+	TypeToggle MessageType = "toggle"
+	TypeTick   MessageType = "tick"
+	TypeResize MessageType = "resize"
+	TypeMode   MessageType = "mode"
+)
+
+// Hello announces a new player to the server. The server echoes it back
+// to every client with PlayerID filled in, including the sender.
+type Hello struct {
+	Name     string `json:"name"`
+	PlayerID int    `json:"player_id"`
+}
+
+// Toggle flips a single cell. The server overwrites PlayerID with the
+// sending player's id before broadcasting, whatever the client put there.
+type Toggle struct {
+	X        int `json:"x"`
+	Y        int `json:"y"`
+	PlayerID int `json:"player_id"`
+}
+
+// CellDiff is a single cell whose state changed during a generation.
+type CellDiff struct {
+	X     int  `json:"x"`
+	Y     int  `json:"y"`
+	State byte `json:"state"`
+}
+
+// Tick broadcasts the cells that changed when the server advanced a
+// generation.
+type Tick struct {
+	Generation int        `json:"generation"`
+	Diffs      []CellDiff `json:"diffs"`
+}
+
+// Resize announces the authoritative board dimensions and rule a newly
+// connected client must adopt.
+type Resize struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Rule   string `json:"rule"`
+}
+
+// Mode announces whether the server is evolving the board and at what
+// pace.
+type Mode struct {
+	Evolution bool `json:"evolution"`
+	DelayMs   int  `json:"delay_ms"`
+}
+
+// Envelope wraps a typed, JSON-encoded payload for transport.
+type Envelope struct {
+	Type MessageType     `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Conn reads and writes length-prefixed Envelopes over an underlying
+// stream.
+type Conn struct {
+	rw io.ReadWriter
+}
+
+// NewConn wraps rw (typically a net.Conn) in the Envelope protocol.
+func NewConn(rw io.ReadWriter) *Conn {
+	return &Conn{rw: rw}
+}
+
+// Send encodes payload as JSON, tags it with typ and writes it as one
+// length-prefixed frame.
+func (c *Conn) Send(typ MessageType, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(Envelope{Type: typ, Data: data})
+	if err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(buf)))
+	if _, err := c.rw.Write(length[:]); err != nil {
+		return err
+	}
+	_, err = c.rw.Write(buf)
+	return err
+}
+
+// maxFrameSize caps the length prefix so a bogus or corrupted frame
+// can't force an unbounded allocation.
+const maxFrameSize = 8 << 20 // 8 MiB
+
+// Receive blocks for the next frame and decodes its Envelope.
+func (c *Conn) Receive() (Envelope, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(c.rw, length[:]); err != nil {
+		return Envelope{}, err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n > maxFrameSize {
+		return Envelope{}, fmt.Errorf("net: frame of %d bytes exceeds %d byte limit", n, maxFrameSize)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.rw, buf); err != nil {
+		return Envelope{}, err
+	}
+	var env Envelope
+	if err := json.Unmarshal(buf, &env); err != nil {
+		return Envelope{}, err
+	}
+	return env, nil
+}
+
+// Decode unmarshals an Envelope's Data into v.
+func (env Envelope) Decode(v interface{}) error {
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		return fmt.Errorf("net: decoding %s frame: %w", env.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/alaruss/game_of_life/sim"
+)
+
+// LoadRLE parses a pattern in the RLE format used by most Life pattern
+// collections and stamps its live cells into the board at offsetX,
+// offsetY.
+func (game *GameOfLife) LoadRLE(r io.Reader, offsetX int, offsetY int) error {
+	return game.board.LoadRLE(r, offsetX, offsetY)
+}
+
+// LoadLife106 parses a pattern in the older "#Life 1.06" coordinate-list
+// format and stamps its live cells into the board.
+func (game *GameOfLife) LoadLife106(r io.Reader) error {
+	return game.board.LoadLife106(r)
+}
+
+// patternPicker holds the state of the modal pattern browser opened with
+// the 'l' key.
+type patternPicker struct {
+	dir   string
+	files []string
+	index int
+}
+
+// openPicker lists the *.rle files in game.patternsDir and shows the
+// picker modal, pausing evolution while it is open.
+func (game *GameOfLife) openPicker() {
+	if game.patternsDir == "" {
+		return
+	}
+	entries, err := ioutil.ReadDir(game.patternsDir)
+	if err != nil {
+		return
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".rle") {
+			files = append(files, e.Name())
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	sort.Strings(files)
+	game.evolution = false
+	game.picker = &patternPicker{dir: game.patternsDir, files: files}
+	game.drawPicker()
+}
+
+func (game *GameOfLife) closePicker() {
+	game.picker = nil
+	game.screen.Sync()
+}
+
+func (game *GameOfLife) movePicker(delta int) {
+	if game.picker == nil {
+		return
+	}
+	n := len(game.picker.files)
+	game.picker.index = ((game.picker.index+delta)%n + n) % n
+	game.drawPicker()
+}
+
+// selectPicker loads the currently highlighted pattern, centers it on
+// the field and closes the modal.
+func (game *GameOfLife) selectPicker() {
+	if game.picker == nil {
+		return
+	}
+	name := game.picker.files[game.picker.index]
+	path := filepath.Join(game.picker.dir, name)
+
+	w, h := 0, 0
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			w, h, _, _ = sim.ParseRLEHeader(line)
+			break
+		}
+		f.Close()
+	}
+	offsetX := (game.board.Width - w) / 2
+	offsetY := (game.board.Height - h) / 2
+
+	f, err := os.Open(path)
+	if err == nil {
+		if err := game.LoadRLE(f, offsetX, offsetY); err == nil {
+			for y := 0; y < game.board.Height; y++ {
+				for x := 0; x < game.board.Width; x++ {
+					if game.board.Get(x, y) == 1 {
+						game.drawCell(x, y, 1)
+					}
+				}
+			}
+			game.render()
+		}
+		f.Close()
+	}
+	game.closePicker()
+}
+
+// drawPicker renders the modal file list over the top-left of the
+// field.
+func (game *GameOfLife) drawPicker() {
+	style := tcell.StyleDefault.Reverse(true)
+	x0, y0 := game.stateWidth+2, 1
+	width := 30
+	for i, name := range game.picker.files {
+		lineStyle := tcell.StyleDefault
+		if i == game.picker.index {
+			lineStyle = style
+		}
+		line := name
+		if len(line) > width {
+			line = line[:width]
+		}
+		emitStr(game.screen, x0, y0+i, lineStyle, fmt.Sprintf("%-*s", width, line))
+	}
+	game.screen.Show()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// tile is one off-screen cell: the rune and style that should be
+// showing at that position once render is called.
+type tile struct {
+	r     rune
+	style tcell.Style
+}
+
+// liveStats holds the numbers shown by the FPS/TPS/heap counter toggled
+// with the 'f' key.
+type liveStats struct {
+	tps   float64
+	fps   float64
+	alloc uint64
+}
+
+func (s liveStats) String() string {
+	return fmt.Sprintf("%.0ft/s %.0ff/s %s", s.tps, s.fps, formatBytes(s.alloc))
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// allocBuffers (re)allocates the front/back tile buffers for the
+// current width and height, forcing a full redraw on the next render.
+func (game *GameOfLife) allocBuffers() {
+	n := game.board.Width * game.board.Height
+	game.buffer = make([]tile, n)
+	game.prevBuffer = make([]tile, n)
+	for i := range game.buffer {
+		game.buffer[i] = tile{r: ' '}
+		game.prevBuffer[i] = tile{r: 0}
+	}
+}
+
+// drawCell stamps state into the off-screen buffer at (x, y); it is not
+// visible until render is called.
+func (game *GameOfLife) drawCell(x int, y int, state byte) {
+	game.drawCellStyled(x, y, state, tcell.StyleDefault)
+}
+
+// drawCellStyled is like drawCell but lets the caller pick the live
+// style, used to color in remote players' moves.
+func (game *GameOfLife) drawCellStyled(x int, y int, state byte, style tcell.Style) {
+	r := rune(' ')
+	if state == 1 {
+		r = 'X'
+	} else {
+		style = tcell.StyleDefault
+	}
+	game.buffer[y*game.board.Width+x] = tile{r: r, style: style}
+}
+
+// render diffs the off-screen buffer against what was last flushed to
+// the screen and only issues SetContent for the tiles that changed,
+// then shows the result.
+func (game *GameOfLife) render() {
+	now := time.Now()
+	if !game.lastRenderAt.IsZero() {
+		if d := now.Sub(game.lastRenderAt); d > 0 {
+			game.stats.fps = float64(time.Second) / float64(d)
+		}
+	}
+	game.lastRenderAt = now
+
+	for i, t := range game.buffer {
+		if t == game.prevBuffer[i] {
+			continue
+		}
+		x := i % game.board.Width
+		y := i / game.board.Width
+		game.screen.SetContent(x+game.stateWidth, y, t.r, nil, t.style)
+		game.prevBuffer[i] = t
+	}
+	if game.showStats {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		game.stats.alloc = mem.Alloc
+	}
+	game.screen.Show()
+}
+
+func (game *GameOfLife) toggleStats() {
+	game.showStats = !game.showStats
+	game.drawState()
+	game.screen.Show()
+}
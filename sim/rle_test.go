@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRLEHeader(t *testing.T) {
+	w, h, rule, err := ParseRLEHeader("x = 3, y = 3, rule = B36/S23")
+	if err != nil {
+		t.Fatalf("ParseRLEHeader: %v", err)
+	}
+	if w != 3 || h != 3 {
+		t.Fatalf("got w=%d h=%d, want 3, 3", w, h)
+	}
+	if rule.String() != "B36/S23" {
+		t.Fatalf("rule = %v, want B36/S23", rule)
+	}
+}
+
+func TestParseRLEHeaderNoRule(t *testing.T) {
+	_, _, rule, err := ParseRLEHeader("x = 3, y = 3")
+	if err != nil {
+		t.Fatalf("ParseRLEHeader: %v", err)
+	}
+	if rule.Name != "" {
+		t.Fatalf("rule = %v, want zero value when header declares none", rule)
+	}
+}
+
+func TestLoadRLEGlider(t *testing.T) {
+	const glider = "x = 3, y = 3, rule = B3/S23\nbob$2bo$3o!"
+	b := NewBoard(10, 10, ConwayRule)
+	if err := b.LoadRLE(strings.NewReader(glider), 0, 0); err != nil {
+		t.Fatalf("LoadRLE: %v", err)
+	}
+	want := map[[2]int]bool{{1, 0}: true, {2, 1}: true, {0, 2}: true, {1, 2}: true, {2, 2}: true}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			alive := b.Get(x, y) == 1
+			if alive != want[[2]int{x, y}] {
+				t.Errorf("(%d,%d) alive=%v, want %v", x, y, alive, want[[2]int{x, y}])
+			}
+		}
+	}
+}
+
+func TestLoadRLEAppliesDeclaredRule(t *testing.T) {
+	const pattern = "x = 1, y = 1, rule = B36/S23\no!"
+	b := NewBoard(5, 5, ConwayRule)
+	if err := b.LoadRLE(strings.NewReader(pattern), 0, 0); err != nil {
+		t.Fatalf("LoadRLE: %v", err)
+	}
+	if b.Rule.String() != "B36/S23" {
+		t.Fatalf("Rule = %v, want the rule declared in the header", b.Rule)
+	}
+}
+
+func TestLoadLife106(t *testing.T) {
+	const pattern = "#Life 1.06\n1 1\n2 2\n"
+	b := NewBoard(5, 5, ConwayRule)
+	if err := b.LoadLife106(strings.NewReader(pattern)); err != nil {
+		t.Fatalf("LoadLife106: %v", err)
+	}
+	if b.Get(1, 1) != 1 || b.Get(2, 2) != 1 {
+		t.Fatalf("expected (1,1) and (2,2) alive")
+	}
+	if b.Get(0, 0) != 0 {
+		t.Fatalf("expected (0,0) dead")
+	}
+}
@@ -0,0 +1,262 @@
+// Package sim holds the tcell-independent core of the simulation: the
+// field storage, the ruleset and the generation step. Both the
+// interactive UI and the -headless runner share this package so the
+// two can never drift apart.
+package sim
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Rule describes a Life-like cellular automaton rule: Birth[n] is true
+// if a dead cell with n live neighbors becomes alive, Survive[n] is true
+// if a live cell with n live neighbors stays alive.
+type Rule struct {
+	Name    string
+	Birth   [9]bool
+	Survive [9]bool
+}
+
+// ParseRule parses the standard "B3/S23" notation into a Rule.
+func ParseRule(s string) (Rule, error) {
+	var rule Rule
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return rule, fmt.Errorf("rule: expected B.../S... notation, got %q", s)
+	}
+	bPart, sPart := parts[0], parts[1]
+	if len(sPart) > 0 && (sPart[0] == 'B' || sPart[0] == 'b') {
+		bPart, sPart = sPart, bPart
+	}
+	if len(bPart) == 0 || (bPart[0] != 'B' && bPart[0] != 'b') {
+		return rule, fmt.Errorf("rule: missing B in %q", s)
+	}
+	if len(sPart) == 0 || (sPart[0] != 'S' && sPart[0] != 's') {
+		return rule, fmt.Errorf("rule: missing S in %q", s)
+	}
+	if err := fillDigits(bPart[1:], &rule.Birth); err != nil {
+		return rule, err
+	}
+	if err := fillDigits(sPart[1:], &rule.Survive); err != nil {
+		return rule, err
+	}
+	rule.Name = strings.ToUpper(bPart) + "/" + strings.ToUpper(sPart)
+	return rule, nil
+}
+
+func fillDigits(digits string, target *[9]bool) error {
+	for _, c := range digits {
+		n, err := strconv.Atoi(string(c))
+		if err != nil || n > 8 {
+			return fmt.Errorf("rule: invalid neighbor count %q", string(c))
+		}
+		target[n] = true
+	}
+	return nil
+}
+
+// String renders the rule back into "B.../S..." notation.
+func (rule Rule) String() string {
+	var b, s strings.Builder
+	b.WriteString("B")
+	s.WriteString("S")
+	for n := 0; n <= 8; n++ {
+		if rule.Birth[n] {
+			fmt.Fprintf(&b, "%d", n)
+		}
+		if rule.Survive[n] {
+			fmt.Fprintf(&s, "%d", n)
+		}
+	}
+	return b.String() + "/" + s.String()
+}
+
+// ConwayRule is the classic B3/S23 ruleset.
+var ConwayRule = MustParseRule("B3/S23")
+
+// Presets are cycled through with the UI's rule hotkey.
+var Presets = []Rule{
+	MustParseRule("B3/S23"),       // Conway
+	MustParseRule("B36/S23"),      // HighLife
+	MustParseRule("B3678/S34678"), // Day & Night
+	MustParseRule("B2/S"),         // Seeds
+	MustParseRule("B1357/S1357"),  // Replicator
+}
+
+// MustParseRule parses s and panics if it is malformed; used for the
+// presets above, whose notation is known-good at compile time.
+func MustParseRule(s string) Rule {
+	rule, err := ParseRule(s)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// NextRule returns the preset that follows current in Presets, wrapping
+// around and defaulting to the first preset if current isn't one of
+// them.
+func NextRule(current Rule) Rule {
+	for i, preset := range Presets {
+		if preset.String() == current.String() {
+			return Presets[(i+1)%len(Presets)]
+		}
+	}
+	return Presets[0]
+}
+
+// CellDiff is a single cell whose state changed during a generation.
+type CellDiff struct {
+	X     int
+	Y     int
+	State byte
+}
+
+// Board is a toroidal Life-like field: cell (x, y) lives at
+// Field[y*Width+x].
+type Board struct {
+	Width      int
+	Height     int
+	Generation int
+	Field      []byte
+	Rule       Rule
+}
+
+// NewBoard allocates an empty w x h board governed by rule. Negative
+// w or h (reachable from e.g. a terminal narrower than the UI's side
+// column) are floored at 0 rather than panicking on the allocation.
+func NewBoard(w int, h int, rule Rule) *Board {
+	if w < 0 {
+		w = 0
+	}
+	if h < 0 {
+		h = 0
+	}
+	return &Board{
+		Width:  w,
+		Height: h,
+		Field:  make([]byte, w*h),
+		Rule:   rule,
+	}
+}
+
+// Get returns the state of cell (x, y), returning 0 for out-of-bounds
+// coordinates to match Set's leniency.
+func (b *Board) Get(x int, y int) byte {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return 0
+	}
+	return b.Field[y*b.Width+x]
+}
+
+// Set writes state into cell (x, y), ignoring out-of-bounds
+// coordinates.
+func (b *Board) Set(x int, y int, state byte) {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return
+	}
+	b.Field[y*b.Width+x] = state
+}
+
+func (b *Board) neighbors(x int, y int) int {
+	n := 0
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			nx, ny := x+i, y+j
+			if nx < 0 {
+				nx = b.Width - 1
+			} else if nx == b.Width {
+				nx = 0
+			}
+			if ny < 0 {
+				ny = b.Height - 1
+			} else if ny == b.Height {
+				ny = 0
+			}
+			n += int(b.Field[ny*b.Width+nx])
+		}
+	}
+	return n
+}
+
+// NextCellState computes the state cell (x, y) should have after one
+// generation under b.Rule.
+func (b *Board) NextCellState(x int, y int) byte {
+	n := b.neighbors(x, y)
+	if b.Field[y*b.Width+x] == 1 {
+		if b.Rule.Survive[n] {
+			return 1
+		}
+		return 0
+	}
+	if b.Rule.Birth[n] {
+		return 1
+	}
+	return 0
+}
+
+// Tick advances the board by one generation and returns the cells that
+// changed.
+func (b *Board) Tick() []CellDiff {
+	next := make([]byte, b.Width*b.Height)
+	var diffs []CellDiff
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			i := y*b.Width + x
+			next[i] = b.NextCellState(x, y)
+			if next[i] != b.Field[i] {
+				diffs = append(diffs, CellDiff{X: x, Y: y, State: next[i]})
+			}
+		}
+	}
+	b.Field = next
+	b.Generation++
+	return diffs
+}
+
+// Resize copies the board's current content into a new w x h board,
+// truncating or padding as needed.
+func (b *Board) Resize(w int, h int) *Board {
+	next := NewBoard(w, h, b.Rule)
+	next.Generation = b.Generation
+	for y := 0; y < h && y < b.Height; y++ {
+		for x := 0; x < w && x < b.Width; x++ {
+			next.Field[y*w+x] = b.Field[y*b.Width+x]
+		}
+	}
+	return next
+}
+
+// SeedRandom fills the board with live cells at the given density
+// in [0, 1], using rnd so callers can reproduce a run.
+func (b *Board) SeedRandom(rnd *rand.Rand, density float64) {
+	for i := range b.Field {
+		if rnd.Float64() < density {
+			b.Field[i] = 1
+		} else {
+			b.Field[i] = 0
+		}
+	}
+}
+
+// LiveCount returns the number of live cells on the board.
+func (b *Board) LiveCount() int {
+	n := 0
+	for _, s := range b.Field {
+		n += int(s)
+	}
+	return n
+}
+
+// Hash returns the SHA-1 digest of the board's field, letting callers
+// check two runs produced an identical final state.
+func (b *Board) Hash() [sha1.Size]byte {
+	return sha1.Sum(b.Field)
+}
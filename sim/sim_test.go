@@ -0,0 +1,103 @@
+package sim
+
+import "testing"
+
+func TestParseRuleRoundTrip(t *testing.T) {
+	rule, err := ParseRule("B3/S23")
+	if err != nil {
+		t.Fatalf("ParseRule: %v", err)
+	}
+	if !rule.Birth[3] || !rule.Survive[2] || !rule.Survive[3] {
+		t.Fatalf("ParseRule(%q) = %+v, wrong digits", "B3/S23", rule)
+	}
+	if got := rule.String(); got != "B3/S23" {
+		t.Fatalf("String() = %q, want %q", got, "B3/S23")
+	}
+}
+
+func TestParseRuleOrderAndCase(t *testing.T) {
+	rule, err := ParseRule("s23/b3")
+	if err != nil {
+		t.Fatalf("ParseRule: %v", err)
+	}
+	if got := rule.String(); got != "B3/S23" {
+		t.Fatalf("String() = %q, want %q", got, "B3/S23")
+	}
+}
+
+func TestParseRuleInvalid(t *testing.T) {
+	for _, s := range []string{"garbage", "B3", "B9/S23", "B3/X23"} {
+		if _, err := ParseRule(s); err == nil {
+			t.Errorf("ParseRule(%q) = nil error, want error", s)
+		}
+	}
+}
+
+func TestNextRuleCyclesAndDefaults(t *testing.T) {
+	if got := NextRule(Presets[0]); got.String() != Presets[1].String() {
+		t.Fatalf("NextRule(Presets[0]) = %v, want %v", got, Presets[1])
+	}
+	if got := NextRule(Presets[len(Presets)-1]); got.String() != Presets[0].String() {
+		t.Fatalf("NextRule(last preset) = %v, want wraparound to %v", got, Presets[0])
+	}
+	unknown := MustParseRule("B3/S")
+	if got := NextRule(unknown); got.String() != Presets[0].String() {
+		t.Fatalf("NextRule(unknown) = %v, want %v", got, Presets[0])
+	}
+}
+
+func TestBoardGetSetOutOfBounds(t *testing.T) {
+	b := NewBoard(10, 10, ConwayRule)
+	b.Set(50, 50, 1)
+	if got := b.Get(50, 50); got != 0 {
+		t.Fatalf("Get(50, 50) = %d, want 0", got)
+	}
+	b.Set(3, 4, 1)
+	if got := b.Get(3, 4); got != 1 {
+		t.Fatalf("Get(3, 4) = %d, want 1", got)
+	}
+}
+
+func TestBoardTickBlinker(t *testing.T) {
+	b := NewBoard(5, 5, ConwayRule)
+	b.Set(1, 2, 1)
+	b.Set(2, 2, 1)
+	b.Set(3, 2, 1)
+
+	b.Tick()
+	want := map[[2]int]bool{{2, 1}: true, {2, 2}: true, {2, 3}: true}
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			alive := b.Get(x, y) == 1
+			if alive != want[[2]int{x, y}] {
+				t.Fatalf("after tick, (%d,%d) alive=%v, want %v", x, y, alive, want[[2]int{x, y}])
+			}
+		}
+	}
+	if b.Generation != 1 {
+		t.Fatalf("Generation = %d, want 1", b.Generation)
+	}
+}
+
+func TestNewBoardClampsNegativeSize(t *testing.T) {
+	b := NewBoard(-5, 10, ConwayRule)
+	if b.Width != 0 {
+		t.Fatalf("Width = %d, want 0 for a negative size hint", b.Width)
+	}
+	if len(b.Field) != 0 {
+		t.Fatalf("Field has %d cells, want 0", len(b.Field))
+	}
+}
+
+func TestBoardResize(t *testing.T) {
+	b := NewBoard(3, 3, ConwayRule)
+	b.Set(2, 2, 1)
+	resized := b.Resize(2, 2)
+	if resized.Get(2, 2) != 0 {
+		t.Fatalf("cell outside the shrunk board should be dropped")
+	}
+	grown := b.Resize(4, 4)
+	if grown.Get(2, 2) != 1 {
+		t.Fatalf("cell within the grown board should be preserved")
+	}
+}
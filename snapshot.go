@@ -0,0 +1,225 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/alaruss/game_of_life/sim"
+)
+
+const snapshotMagic = "GOL1"
+
+// replaceBoard installs a new board and repaints the screen from it, the
+// common path resize and snapshot loading both go through so redraw
+// stays consistent.
+func (game *GameOfLife) replaceBoard(board *sim.Board) {
+	game.screen.Fill(' ', tcell.StyleDefault)
+	game.board = board
+	game.allocBuffers()
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			if board.Get(x, y) == 1 {
+				game.drawCell(x, y, 1)
+			}
+		}
+	}
+	game.render()
+}
+
+func packField(field []byte) []byte {
+	buf := make([]byte, (len(field)+7)/8)
+	for i, state := range field {
+		if state == 1 {
+			buf[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return buf
+}
+
+func unpackField(buf []byte, w int, h int) ([]byte, error) {
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("snapshot: invalid dimensions %dx%d", w, h)
+	}
+	if len(buf) < (w*h+7)/8 {
+		return nil, fmt.Errorf("snapshot: bitmap too short for %dx%d board", w, h)
+	}
+	field := make([]byte, w*h)
+	for i := range field {
+		if buf[i/8]&(1<<uint(i%8)) != 0 {
+			field[i] = 1
+		}
+	}
+	return field, nil
+}
+
+// SaveSnapshot writes width, height, generation, delay, evolution state
+// and a gzipped bitmap of the field to path.
+func (game *GameOfLife) SaveSnapshot(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(snapshotMagic); err != nil {
+		return err
+	}
+	header := make([]byte, 21)
+	binary.BigEndian.PutUint32(header[0:4], uint32(game.board.Width))
+	binary.BigEndian.PutUint32(header[4:8], uint32(game.board.Height))
+	binary.BigEndian.PutUint32(header[8:12], uint32(game.board.Generation))
+	binary.BigEndian.PutUint64(header[12:20], uint64(game.delay))
+	if game.evolution {
+		header[20] = 1
+	}
+	if _, err := f.Write(header); err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write(packField(game.board.Field)); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// LoadSnapshot restores a board previously written by SaveSnapshot.
+func (game *GameOfLife) LoadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return err
+	}
+	if string(magic) != snapshotMagic {
+		return fmt.Errorf("snapshot: %s is not a game-of-life snapshot", path)
+	}
+
+	header := make([]byte, 21)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return err
+	}
+	w := int(binary.BigEndian.Uint32(header[0:4]))
+	h := int(binary.BigEndian.Uint32(header[4:8]))
+	gen := int(binary.BigEndian.Uint32(header[8:12]))
+	delay := time.Duration(binary.BigEndian.Uint64(header[12:20]))
+	evolution := header[20] == 1
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	bitmap, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return err
+	}
+
+	field, err := unpackField(bitmap, w, h)
+	if err != nil {
+		return err
+	}
+
+	game.delay = delay
+	game.evolution = evolution
+	board := sim.NewBoard(w, h, game.board.Rule)
+	board.Generation = gen
+	board.Field = field
+	game.replaceBoard(board)
+	return nil
+}
+
+// textPrompt is the filename prompt drawn in the state column for the
+// 's' (save) and 'o' (load) keys.
+type textPrompt struct {
+	save   bool
+	buffer string
+}
+
+func (game *GameOfLife) openSavePrompt() {
+	game.prompt = &textPrompt{save: true}
+	game.drawPrompt()
+}
+
+func (game *GameOfLife) openLoadPrompt() {
+	game.prompt = &textPrompt{save: false}
+	game.drawPrompt()
+}
+
+func (game *GameOfLife) promptAppend(r rune) {
+	if game.prompt == nil {
+		return
+	}
+	game.prompt.buffer += string(r)
+	game.drawPrompt()
+}
+
+func (game *GameOfLife) promptBackspace() {
+	if game.prompt == nil || len(game.prompt.buffer) == 0 {
+		return
+	}
+	game.prompt.buffer = game.prompt.buffer[:len(game.prompt.buffer)-1]
+	game.drawPrompt()
+}
+
+func (game *GameOfLife) closePrompt() {
+	game.prompt = nil
+	game.drawState()
+	game.screen.Show()
+}
+
+func (game *GameOfLife) submitPrompt() {
+	if game.prompt == nil || game.prompt.buffer == "" {
+		game.closePrompt()
+		return
+	}
+	if game.prompt.save {
+		game.SaveSnapshot(game.prompt.buffer)
+	} else {
+		game.LoadSnapshot(game.prompt.buffer)
+	}
+	game.closePrompt()
+}
+
+func (game *GameOfLife) promptRow() int {
+	row := 4
+	if game.showStats {
+		row++
+	}
+	return row + len(game.players)
+}
+
+func (game *GameOfLife) drawPrompt() {
+	label := "Save: "
+	if !game.prompt.save {
+		label = "Load: "
+	}
+	emitStr(game.screen, 0, game.promptRow(), tcell.StyleDefault, label+game.prompt.buffer+"_")
+	game.screen.Show()
+}
+
+// softReset clears the field and generation counter, keeping the
+// current size and delay.
+func (game *GameOfLife) softReset() {
+	game.evolution = false
+	game.replaceBoard(sim.NewBoard(game.board.Width, game.board.Height, game.board.Rule))
+	game.drawState()
+	game.screen.Show()
+}
+
+// hardReset additionally restores the default delay.
+func (game *GameOfLife) hardReset() {
+	game.delay = defaultDelay
+	game.softReset()
+}